@@ -3,6 +3,7 @@ package args
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // Removes and returns the first argument, regardless of its form. Returns a
@@ -15,8 +16,25 @@ func Param(args []string) ([]string, string, bool) {
 	}
 }
 
-// Looks for a single argument of the form "--flag" or "-f". Removes only that
-// argument, and returns a bool indicating whether it was found.
+// Finds the index of the first "--" sentinel in args, or -1 if there
+// isn't one. Flags and options are never matched at or beyond it.
+func sentinelIndex(args []string) int {
+	for i, arg := range(args) {
+		if arg == "--" {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Looks for a single argument of the form "--flag", "-f", or (for
+// single-character names) as part of a bundle of short flags like
+// "-abc". Removes only the matched flag (or, for a bundle, just its
+// letter), and returns a bool indicating whether it was found. Only a
+// bundle's leading letter is matched, so the flags of "-abc" are found
+// one call at a time in the order a, b, c; "b" is not found in "-abc"
+// itself.
 func Flag(args []string, name string, names...string) ([]string, bool) {
 	lookFor := make([]string, 0, len(names)+1)
 
@@ -32,19 +50,36 @@ func Flag(args []string, name string, names...string) ([]string, bool) {
 		}
 	}
 
+	boundary := sentinelIndex(args)
+
 	for i, arg := range(args) {
+		if boundary >= 0 && i >= boundary {
+			break
+		}
+
 		for _, name := range(lookFor) {
 			if arg == name {
 				return append(args[0:i], args[i+1:]...), true
 			}
+
+			if len(name) == 2 && strings.HasPrefix(arg, name) && len(arg) > 2 && arg[2] != '=' {
+				out := append(append([]string{}, args[0:i]...), "-" + arg[2:])
+				out = append(out, args[i+1:]...)
+				return out, true
+			}
 		}
 	}
 
 	return args, false
 }
 
-// Looks for arguments of the form "--name value" or "-n value". If the option
-// name is found with no argument following it, an error will be returned.
+// Looks for arguments of the form "--name value", "-n value",
+// "--name=value", "-n=value", or (for single-character names) a value
+// attached directly, as in "-nvalue", which is also what remains of a
+// bundle like "-abcvalue" once Flag has removed "a" and "b". The option
+// letter must lead the token: "c" is not found in "-abcvalue" itself.
+// If the option name is found with no argument following it, an error
+// will be returned.
 func Option(args []string, name string, names...string) ([]string, string, bool, error) {
 	lookFor := make([]string, 0, len(names)+1)
 
@@ -60,7 +95,13 @@ func Option(args []string, name string, names...string) ([]string, string, bool,
 		}
 	}
 
+	boundary := sentinelIndex(args)
+
 	for i, arg := range(args) {
+		if boundary >= 0 && i >= boundary {
+			break
+		}
+
 		for _, name := range(lookFor) {
 			if arg == name {
 				if i == len(args) - 1 {
@@ -70,6 +111,16 @@ func Option(args []string, name string, names...string) ([]string, string, bool,
 					return append(args[0:i], args[i+2:]...), val, true, nil
 				}
 			}
+
+			if strings.HasPrefix(arg, name + "=") {
+				val := arg[len(name)+1:]
+				return append(args[0:i], args[i+1:]...), val, true, nil
+			}
+
+			if len(name) == 2 && strings.HasPrefix(arg, name) && len(arg) > 2 && arg[2] != '=' {
+				val := arg[2:]
+				return append(args[0:i], args[i+1:]...), val, true, nil
+			}
 		}
 	}
 
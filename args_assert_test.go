@@ -0,0 +1,26 @@
+package args
+
+import "testing"
+
+func assertBoolEquals(t *testing.T, expected, actual bool) {
+	if expected != actual {
+		t.Errorf("Expected %v, got %v.", expected, actual)
+	}
+}
+
+func assertStringEquals(t *testing.T, expected, actual string) {
+	if expected != actual {
+		t.Errorf("Expected %q, got %q.", expected, actual)
+	}
+}
+
+func assertParamNamed(t *testing.T, args Args, name, expected string) {
+	if !args.HasParamNamed(name) {
+		t.Errorf("Expected a parameter named %q.", name)
+		return
+	}
+
+	if actual := args.ParamNamed(name); actual != expected {
+		t.Errorf("Expected parameter %q to be %q, got %q.", name, expected, actual)
+	}
+}
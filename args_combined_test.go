@@ -0,0 +1,106 @@
+package args
+
+import (
+	. "testing"
+)
+
+func TestOptionEqualsJoined(t *T) {
+	args, value, ok, err := Option([]string{"--bar=fizz", "-w=-x"}, "b", "bar")
+	if !argsEqual(args, []string{"-w=-x"}) {
+		t.Errorf("Should have consumed the '--name=value' option, got %v.", args)
+	}
+	if value != "fizz" || !ok || err != nil {
+		t.Errorf("Should have matched '--bar=fizz', got %q, %v, %v.", value, ok, err)
+	}
+
+	args, value, ok, err = Option(args, "w")
+	if !argsEqual(args, []string{}) {
+		t.Errorf("Should have consumed the '-n=value' option, got %v.", args)
+	}
+	if value != "-x" || !ok || err != nil {
+		t.Errorf("Should have matched '-w=-x' with a value starting with a hyphen, got %q, %v, %v.", value, ok, err)
+	}
+}
+
+func TestOptionAttachedValue(t *T) {
+	args, value, ok, err := Option([]string{"-fvalue"}, "f")
+	if !argsEqual(args, []string{}) {
+		t.Errorf("Should have consumed the attached-value option, got %v.", args)
+	}
+	if value != "value" || !ok || err != nil {
+		t.Errorf("Should have matched '-fvalue', got %q, %v, %v.", value, ok, err)
+	}
+}
+
+func TestFlagBundle(t *T) {
+	args, ok := Flag([]string{"-abc"}, "a")
+	if !ok {
+		t.Errorf("Should have found 'a' in the bundle.")
+	}
+	if !argsEqual(args, []string{"-bc"}) {
+		t.Errorf("Should have stripped 'a', leaving the rest of the bundle, got %v.", args)
+	}
+
+	args, ok = Flag(args, "b")
+	if !ok {
+		t.Errorf("Should have found 'b' in the bundle.")
+	}
+	if !argsEqual(args, []string{"-c"}) {
+		t.Errorf("Should have stripped 'b', leaving the rest of the bundle, got %v.", args)
+	}
+
+	args, ok = Flag(args, "c")
+	if !ok {
+		t.Errorf("Should have found 'c' in the bundle.")
+	}
+	if !argsEqual(args, []string{}) {
+		t.Errorf("Should have consumed the last flag in the bundle entirely, got %v.", args)
+	}
+}
+
+func TestSentinelStopsFlagAndOptionMatching(t *T) {
+	args, ok := Flag([]string{"--", "-f"}, "f")
+	if ok {
+		t.Errorf("Should not have matched a flag after the '--' sentinel.")
+	}
+	if !argsEqual(args, []string{"--", "-f"}) {
+		t.Errorf("Should not have modified anything, got %v.", args)
+	}
+
+	args, value, found, err := Option([]string{"--", "-o", "x"}, "o")
+	if found || value != "" || err != nil {
+		t.Errorf("Should not have matched an option after the '--' sentinel, got %q, %v, %v.", value, found, err)
+	}
+	if !argsEqual(args, []string{"--", "-o", "x"}) {
+		t.Errorf("Should not have modified anything, got %v.", args)
+	}
+}
+
+func TestChainCombinedSyntax(t *T) {
+	result := Load([]string{"-abc", "value", "--name=joined"}).
+		ExpectFlag("a").
+		ExpectFlag("b").
+		ExpectOption("c").
+		ExpectOption("name")
+
+	assertBoolEquals(t, true, result.Flag("a"))
+	assertBoolEquals(t, true, result.Flag("b"))
+	assertStringEquals(t, "value", result.Option("c"))
+	assertStringEquals(t, "joined", result.Option("name"))
+
+	_, err := result.Validate()
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+}
+
+func TestChainSentinelMarksPositionals(t *T) {
+	result, tail := Load([]string{"-v", "--", "-x", "--yes"}).
+		AllowFlag("v").
+		ChopSlice()
+
+	assertBoolEquals(t, true, result.Flag("v"))
+	if !argsEqual(tail, []string{"-x", "--yes"}) {
+		t.Errorf("Should have returned everything after '--' verbatim, got %v.", tail)
+	}
+}
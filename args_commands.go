@@ -0,0 +1,169 @@
+package args
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A single registered subcommand.
+type command struct {
+	name    string
+	aliases []string
+	handler func(Args) error
+}
+
+func (c command) names() []string {
+	return append([]string{c.name}, c.aliases...)
+}
+
+// Command is the public, read-only view of a registered subcommand,
+// returned by Commands() for help generation.
+type Command struct {
+	Name    string
+	Aliases []string
+}
+
+// Registers a subcommand, matched by Dispatch against the next
+// unconsumed positional.
+// name: The primary name of the command, as a user would type it.
+// handler: Called with a fresh Args scoped to the remaining arguments
+// when this command is matched.
+// aliases: Any other names that should also match this command.
+func (chain argv) Command(name string, handler func(Args) error, aliases ...string) Args {
+	out := chain.clone()
+	out.commands = append(out.commands, command{name: name, aliases: aliases, handler: handler})
+	return out
+}
+
+// Registers a fallback handler for Dispatch, used when there's no
+// positional left to use as a verb, or it doesn't match any registered
+// Command. Without one, Dispatch returns an error in either case.
+func (chain argv) DefaultCommand(handler func(Args) error) Args {
+	out := chain.clone()
+	out.defaultCommand = handler
+	return out
+}
+
+// Returns the subcommands registered so far.
+func (chain argv) Commands() []Command {
+	out := make([]Command, len(chain.commands))
+
+	for i, c := range chain.commands {
+		out[i] = Command{Name: c.name, Aliases: c.aliases}
+	}
+
+	return out
+}
+
+// Consumes the next unconsumed positional as a verb, matches it against
+// the registered commands, and calls its handler with a fresh Args
+// scoped to the remaining (still unconsumed) arguments.
+func (chain argv) Dispatch() error {
+	if chain.probe != nil {
+		chain.probe.scope = chain
+	}
+
+	if shell, ok := chain.completionShellRequested(); ok {
+		script, err := chain.CompletionScript(shell, progName())
+		if err != nil {
+			return err
+		}
+
+		return CompletionRequested{script}
+	}
+
+	if chain.helpRequested() {
+		return HelpRequested{chain.usageString()}
+	}
+
+	verb, tail, found := chain.nextVerb()
+
+	if !found {
+		if chain.defaultCommand != nil {
+			return chain.defaultCommand(chain.subArgs(tail))
+		}
+
+		return fmt.Errorf("No command given; expected one of: %s", strings.Join(chain.commandNames(), ", "))
+	}
+
+	cmd, err := chain.matchCommand(verb)
+	if err != nil {
+		if chain.defaultCommand != nil {
+			return chain.defaultCommand(chain.subArgs(append([]string{verb}, tail...)))
+		}
+
+		return err
+	}
+
+	return cmd.handler(chain.subArgs(tail))
+}
+
+// Builds the Args a matched command's handler (or the default command)
+// is invoked with: a fresh chain scoped to tail, carrying forward the
+// in-progress completion probe, if any.
+func (chain argv) subArgs(tail []string) Args {
+	sub := Load(tail).(argv)
+	sub.probe = chain.probe
+	return sub
+}
+
+// Finds the next unconsumed positional, returning it along with every
+// other still-unconsumed argument that follows it.
+func (chain argv) nextVerb() (verb string, tail []string, found bool) {
+	for i, arg := range chain.args {
+		if chain.consumed[i] {
+			continue
+		}
+
+		verb, found = arg, true
+
+		for j := i + 1; j < len(chain.args); j += 1 {
+			if !chain.consumed[j] {
+				tail = append(tail, chain.args[j])
+			}
+		}
+
+		break
+	}
+
+	return
+}
+
+func (chain argv) commandNames() (names []string) {
+	for _, c := range chain.commands {
+		names = append(names, c.name)
+	}
+
+	return
+}
+
+// Matches verb against the registered commands, first by exact name or
+// alias, then (if nothing matched exactly) by unambiguous prefix.
+func (chain argv) matchCommand(verb string) (command, error) {
+	for _, c := range chain.commands {
+		for _, n := range c.names() {
+			if n == verb {
+				return c, nil
+			}
+		}
+	}
+
+	var matches []command
+	for _, c := range chain.commands {
+		for _, n := range c.names() {
+			if strings.HasPrefix(n, verb) {
+				matches = append(matches, c)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return command{}, fmt.Errorf("Unknown command '%s'; expected one of: %s", verb, strings.Join(chain.commandNames(), ", "))
+	case 1:
+		return matches[0], nil
+	default:
+		return command{}, fmt.Errorf("Command '%s' is ambiguous; could be any of: %s", verb, strings.Join(chain.commandNames(), ", "))
+	}
+}
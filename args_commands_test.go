@@ -0,0 +1,122 @@
+package args
+
+import (
+	. "testing"
+)
+
+func TestDispatch(t *T) {
+	var got []string
+
+	err := Load([]string{"remote", "add", "origin", "URL"}).
+		Command("remote", func(sub Args) error {
+			return sub.Command("add", func(sub Args) error {
+				sub, remaining := sub.ChopSlice()
+				got = remaining
+				return nil
+			}).Dispatch()
+		}).
+		Dispatch()
+
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	if !argsEqual(got, []string{"origin", "URL"}) {
+		t.Errorf("Should have passed the remaining arguments to the nested handler, got %v.", got)
+	}
+}
+
+func TestDispatchAliasAndPrefix(t *T) {
+	called := ""
+
+	handler := func(name string) func(Args) error {
+		return func(Args) error {
+			called = name
+			return nil
+		}
+	}
+
+	chain := Load([]string{"rm"}).
+		Command("remove", handler("remove"), "rm", "delete")
+
+	if err := chain.Dispatch(); err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+	if called != "remove" {
+		t.Errorf("Should have matched the 'rm' alias, got %q.", called)
+	}
+
+	called = ""
+	chain = Load([]string{"rem"}).
+		Command("remove", handler("remove"))
+
+	if err := chain.Dispatch(); err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+	if called != "remove" {
+		t.Errorf("Should have matched 'rem' as an unambiguous prefix, got %q.", called)
+	}
+}
+
+func TestDispatchUnknownCommand(t *T) {
+	chain := Load([]string{"bogus"}).
+		Command("remote", func(Args) error { return nil })
+
+	if err := chain.Dispatch(); err == nil {
+		t.Errorf("Should have returned an error for an unknown command.")
+	}
+}
+
+func TestDispatchDefaultCommand(t *T) {
+	var gotArgs []string
+
+	chain := Load([]string{"bogus", "tail"}).
+		Command("remote", func(Args) error { return nil }).
+		DefaultCommand(func(sub Args) error {
+			_, gotArgs = sub.ChopSlice()
+			return nil
+		})
+
+	if err := chain.Dispatch(); err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	if !argsEqual(gotArgs, []string{"bogus", "tail"}) {
+		t.Errorf("Should have handed the whole tail to the default command, got %v.", gotArgs)
+	}
+}
+
+func TestDispatchHelpRequested(t *T) {
+	err := Load([]string{"--help", "remote"}).
+		Command("remote", func(Args) error { return nil }).
+		Dispatch()
+
+	if _, ok := err.(HelpRequested); !ok {
+		t.Errorf("Should have returned a HelpRequested error, got %T.", err)
+	}
+}
+
+func TestDispatchGenerateCompletionRequested(t *T) {
+	err := Load([]string{"--generate-completion=bash", "remote"}).
+		Command("remote", func(Args) error { return nil }).
+		Dispatch()
+
+	if _, ok := err.(CompletionRequested); !ok {
+		t.Errorf("Should have returned a CompletionRequested error, got %T.", err)
+	}
+}
+
+func TestCommandsIntrospection(t *T) {
+	chain := Load([]string{}).
+		Command("remote", func(Args) error { return nil }, "r")
+
+	commands := chain.Commands()
+	if len(commands) != 1 {
+		t.Fatalf("Should have returned one registered command, got %d.", len(commands))
+	}
+
+	assertStringEquals(t, "remote", commands[0].Name)
+	if !argsEqual(commands[0].Aliases, []string{"r"}) {
+		t.Errorf("Should have returned the command's aliases, got %v.", commands[0].Aliases)
+	}
+}
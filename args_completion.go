@@ -0,0 +1,404 @@
+package args
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompletionHint tells CompletionScript how to suggest values for an
+// option. Complete acts on HintChoices only; after an option with any
+// other hint it suggests the same names it would anywhere else.
+type CompletionHint int
+
+const (
+	// HintNone suggests no values beyond the option's own names.
+	HintNone CompletionHint = iota
+
+	// HintFile suggests filesystem paths.
+	HintFile
+
+	// HintDir suggests directories.
+	HintDir
+
+	// HintHost suggests known hostnames.
+	HintHost
+
+	// HintChoices suggests a fixed list of values, supplied to
+	// CompleteHint.
+	HintChoices
+)
+
+// CompleteHint attaches a completion hint to the option most recently
+// registered with AllowOption/ExpectOption, for use by CompletionScript
+// and Complete. choices is only consulted when hint is HintChoices.
+func (chain argv) CompleteHint(hint CompletionHint, choices ...string) Args {
+	out := chain.clone()
+
+	for i := len(out.usage) - 1; i >= 0; i -= 1 {
+		if out.usage[i].kind == usageOption && out.usage[i].name == out.lastOption {
+			out.usage[i].hint = hint
+			out.usage[i].hintChoices = choices
+			break
+		}
+	}
+
+	return out
+}
+
+// Returned by Validate (and ChopAndValidate) when
+// "--generate-completion=<shell>" was present among the arguments, in
+// place of running normal validation.
+type CompletionRequested struct {
+	// The generated completion script.
+	Script string
+}
+
+// Display string for CompletionRequested; this is the script itself.
+func (c CompletionRequested) Error() string {
+	return c.Script
+}
+
+const generateCompletionPrefix = "--generate-completion="
+
+func (chain argv) completionShellRequested() (shell string, ok bool) {
+	for i, arg := range chain.args {
+		if chain.consumed[i] {
+			continue
+		}
+
+		if chain.afterSentinel >= 0 && i > chain.afterSentinel {
+			continue
+		}
+
+		if strings.HasPrefix(arg, generateCompletionPrefix) {
+			return arg[len(generateCompletionPrefix):], true
+		}
+	}
+
+	return "", false
+}
+
+func progName() string {
+	if len(os.Args) == 0 {
+		return ""
+	}
+
+	return filepath.Base(os.Args[0])
+}
+
+// CompletionScript generates a shell completion script covering every
+// flag, option and subcommand registered so far. Positional parameters
+// are not completed.
+// shell: "bash", "zsh" or "fish".
+// progName: The name the script should register completions for, as
+// typed by the user (typically filepath.Base(os.Args[0])).
+func (chain argv) CompletionScript(shell string, progName string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletionScript(chain, progName), nil
+	case "zsh":
+		return zshCompletionScript(chain, progName), nil
+	case "fish":
+		return fishCompletionScript(chain, progName), nil
+	default:
+		return "", fmt.Errorf("Unsupported shell '%s'; expected bash, zsh or fish.", shell)
+	}
+}
+
+// completionProbe records the deepest Args reached while Complete walks
+// a Dispatch tree, so it can compute candidates from the scope the
+// cursor actually falls in.
+type completionProbe struct {
+	scope argv
+}
+
+// Complete returns candidate completions for words (the command-line
+// tokens, excluding the program name), given the index of the word
+// under the cursor; cword may equal len(words) when completing a new,
+// empty word.
+//
+// If the word before the cursor is a registered option with a
+// HintChoices hint, its choices are suggested directly. Otherwise, if
+// the cursor sits after a recognized verb, the matching command's
+// handler is invoked (scoped to the remaining words) so that its own
+// flags and subcommands are included in the suggestions - exactly as
+// Dispatch would invoke it for real. Handlers should check Validate's
+// error before acting on anything, since Complete offers no stronger
+// guarantee than Dispatch does.
+func (chain argv) Complete(words []string, cword int) []string {
+	if cword < 0 || cword > len(words) {
+		cword = len(words)
+	}
+
+	cur := ""
+	if cword < len(words) {
+		cur = words[cword]
+	}
+
+	if cword > 0 {
+		if choices, ok := chain.choiceHintFor(words[cword-1]); ok {
+			return filterPrefix(choices, cur)
+		}
+	}
+
+	probe := &completionProbe{}
+
+	root := Load(words[:cword]).(argv)
+	root.commands = chain.commands
+	root.defaultCommand = chain.defaultCommand
+	root.usage = chain.usage
+	root.probe = probe
+	probe.scope = root
+
+	func() {
+		defer func() { recover() }()
+		root.Dispatch()
+	}()
+
+	return probe.scope.candidates(cur)
+}
+
+func (chain argv) choiceHintFor(word string) ([]string, bool) {
+	for _, e := range chain.usage {
+		if e.kind != usageOption || e.hint != HintChoices {
+			continue
+		}
+
+		if word == formatFlagName(e.name) {
+			return e.hintChoices, true
+		}
+
+		for _, alt := range e.alts {
+			if word == formatFlagName(alt) {
+				return e.hintChoices, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (chain argv) candidates(prefix string) []string {
+	return filterPrefix(completionWords(chain), prefix)
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	if prefix == "" {
+		return candidates
+	}
+
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// completionWords lists every flag/option name (long and short) and
+// every subcommand name/alias registered on chain.
+func completionWords(chain argv) []string {
+	var words []string
+
+	for _, e := range chain.usage {
+		if e.kind == usageParam {
+			continue
+		}
+
+		words = append(words, formatFlagName(e.name))
+		for _, alt := range e.alts {
+			words = append(words, formatFlagName(alt))
+		}
+	}
+
+	for _, c := range chain.commands {
+		words = append(words, c.names()...)
+	}
+
+	return words
+}
+
+// sanitizeIdent turns name into a safe shell identifier fragment, for
+// use in generated function names.
+func sanitizeIdent(name string) string {
+	var b strings.Builder
+
+	for _, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	return b.String()
+}
+
+func bashCompletionScript(chain argv, prog string) string {
+	fn := "_" + sanitizeIdent(prog) + "_complete"
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# bash completion for %s\n", prog)
+	fmt.Fprintf(&buf, "%s() {\n", fn)
+	fmt.Fprintln(&buf, "\tlocal cur prev")
+	fmt.Fprintln(&buf, "\tcur=\"${COMP_WORDS[COMP_CWORD]}\"")
+	fmt.Fprintln(&buf, "\tprev=\"${COMP_WORDS[COMP_CWORD-1]}\"")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "\tcase \"$prev\" in")
+
+	for _, e := range chain.usage {
+		if e.kind != usageOption || e.hint == HintNone {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "\t%s)\n", strings.Join(optionLabels(e), "|"))
+
+		switch e.hint {
+		case HintFile:
+			fmt.Fprintln(&buf, "\t\tCOMPREPLY=( $(compgen -f -- \"$cur\") )")
+		case HintDir:
+			fmt.Fprintln(&buf, "\t\tCOMPREPLY=( $(compgen -d -- \"$cur\") )")
+		case HintHost:
+			fmt.Fprintln(&buf, "\t\tCOMPREPLY=( $(compgen -A hostname -- \"$cur\") )")
+		case HintChoices:
+			fmt.Fprintf(&buf, "\t\tCOMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(e.hintChoices, " "))
+		}
+
+		fmt.Fprintln(&buf, "\t\treturn\n\t\t;;")
+	}
+
+	fmt.Fprintln(&buf, "\tesac")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "\tCOMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(completionWords(chain), " "))
+	fmt.Fprintln(&buf, "}")
+	fmt.Fprintf(&buf, "complete -F %s %s\n", fn, prog)
+
+	return buf.String()
+}
+
+func zshCompletionScript(chain argv, prog string) string {
+	fn := "_" + sanitizeIdent(prog)
+
+	var specs []string
+	for _, e := range chain.usage {
+		if e.kind != usageFlag && e.kind != usageOption {
+			continue
+		}
+
+		labels := "{" + strings.Join(optionLabels(e), ",") + "}"
+
+		if e.kind == usageFlag {
+			specs = append(specs, fmt.Sprintf("'%s[%s]'", labels, e.description))
+			continue
+		}
+
+		specs = append(specs, fmt.Sprintf("'%s[%s]:%s:%s'", labels, e.description, e.name, zshAction(e)))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#compdef %s\n\n", prog)
+	fmt.Fprintf(&buf, "%s() {\n", fn)
+	fmt.Fprintln(&buf, "\t_arguments \\")
+	for i, spec := range specs {
+		sep := " \\"
+		if i == len(specs)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(&buf, "\t\t%s%s\n", spec, sep)
+	}
+
+	if len(chain.commands) > 0 {
+		var names []string
+		for _, c := range chain.commands {
+			names = append(names, c.names()...)
+		}
+		fmt.Fprintf(&buf, "\t_describe 'command' '(%s)'\n", strings.Join(names, " "))
+	}
+
+	fmt.Fprintln(&buf, "}")
+	fmt.Fprintf(&buf, "\ncompdef %s %s\n", fn, prog)
+
+	return buf.String()
+}
+
+func zshAction(e usageEntry) string {
+	switch e.hint {
+	case HintFile:
+		return "_files"
+	case HintDir:
+		return "_files -/"
+	case HintHost:
+		return "_hosts"
+	case HintChoices:
+		return "(" + strings.Join(e.hintChoices, " ") + ")"
+	default:
+		return ""
+	}
+}
+
+func fishCompletionScript(chain argv, prog string) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# fish completion for %s\n", prog)
+
+	for _, e := range chain.usage {
+		if e.kind == usageParam {
+			continue
+		}
+
+		line := "complete -c " + prog
+
+		for _, n := range append([]string{e.name}, e.alts...) {
+			if len(n) == 1 {
+				line += " -s " + n
+			} else {
+				line += " -l " + n
+			}
+		}
+
+		if e.kind == usageOption {
+			line += " -r"
+
+			switch e.hint {
+			case HintFile:
+				line += " -F"
+			case HintDir:
+				line += " -a '(__fish_complete_directories)'"
+			case HintHost:
+				line += " -a '(__fish_print_hostnames)'"
+			case HintChoices:
+				line += " -a '" + strings.Join(e.hintChoices, " ") + "'"
+			}
+		}
+
+		if e.description != "" {
+			line += " -d '" + e.description + "'"
+		}
+
+		fmt.Fprintln(&buf, line)
+	}
+
+	for _, c := range chain.commands {
+		for _, n := range c.names() {
+			fmt.Fprintf(&buf, "complete -c %s -n '__fish_use_subcommand' -a %s\n", prog, n)
+		}
+	}
+
+	return buf.String()
+}
+
+func optionLabels(e usageEntry) []string {
+	labels := make([]string, 0, len(e.alts)+1)
+	labels = append(labels, formatFlagName(e.name))
+
+	for _, alt := range e.alts {
+		labels = append(labels, formatFlagName(alt))
+	}
+
+	return labels
+}
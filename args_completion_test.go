@@ -0,0 +1,138 @@
+package args
+
+import (
+	. "testing"
+)
+
+func TestCompletionScriptBash(t *T) {
+	chain := Load([]string{}).
+		ExpectOption("dir", "d").CompleteHint(HintDir).
+		AllowFlag("verbose", "v").
+		Command("add", func(Args) error { return nil })
+
+	script, err := chain.CompletionScript("bash", "prog")
+	if err != nil {
+		t.Fatalf("Should not have returned an error: %v", err)
+	}
+
+	if !contains(script, "complete -F") {
+		t.Errorf("Should have registered a completion function, got:\n%s", script)
+	}
+	if !contains(script, "compgen -d") {
+		t.Errorf("Should have used directory completion for the hinted option, got:\n%s", script)
+	}
+	if !contains(script, "--verbose") || !contains(script, "add") {
+		t.Errorf("Should have listed the flag and the subcommand, got:\n%s", script)
+	}
+}
+
+func TestCompletionScriptZsh(t *T) {
+	chain := Load([]string{}).
+		ExpectOption("mode").CompleteHint(HintChoices, "fast", "slow")
+
+	script, err := chain.CompletionScript("zsh", "prog")
+	if err != nil {
+		t.Fatalf("Should not have returned an error: %v", err)
+	}
+
+	if !contains(script, "#compdef prog") {
+		t.Errorf("Should have declared a compdef, got:\n%s", script)
+	}
+	if !contains(script, "(fast slow)") {
+		t.Errorf("Should have listed the choice hint's values, got:\n%s", script)
+	}
+}
+
+func TestCompletionScriptFish(t *T) {
+	chain := Load([]string{}).
+		ExpectOption("host").CompleteHint(HintHost)
+
+	script, err := chain.CompletionScript("fish", "prog")
+	if err != nil {
+		t.Fatalf("Should not have returned an error: %v", err)
+	}
+
+	if !contains(script, "complete -c prog") {
+		t.Errorf("Should have used complete -c, got:\n%s", script)
+	}
+	if !contains(script, "__fish_print_hostnames") {
+		t.Errorf("Should have used hostname completion for the hinted option, got:\n%s", script)
+	}
+}
+
+func TestCompletionScriptUnsupportedShell(t *T) {
+	_, err := Load([]string{}).CompletionScript("powershell", "prog")
+	if err == nil {
+		t.Errorf("Should have returned an error for an unsupported shell.")
+	}
+}
+
+func TestCompleteFlagAndOptionNames(t *T) {
+	chain := Load([]string{}).
+		ExpectOption("repo", "r").
+		AllowFlag("verbose", "v")
+
+	got := chain.Complete([]string{"--ve"}, 0)
+
+	assertStringsEqual(t, []string{"--verbose"}, got)
+}
+
+func TestCompleteChoiceHint(t *T) {
+	chain := Load([]string{}).
+		ExpectOption("mode").CompleteHint(HintChoices, "fast", "slow")
+
+	got := chain.Complete([]string{"--mode", "f"}, 1)
+
+	assertStringsEqual(t, []string{"fast"}, got)
+}
+
+func TestCompleteDelegatesToSubcommand(t *T) {
+	chain := Load([]string{}).
+		Command("remote", func(sub Args) error {
+			_, err := sub.ExpectOption("name", "n").Validate()
+			return err
+		})
+
+	got := chain.Complete([]string{"remote", "--na"}, 1)
+
+	assertStringsEqual(t, []string{"--name"}, got)
+}
+
+func TestGenerateCompletionShortCircuit(t *T) {
+	_, err := Load([]string{"--generate-completion=bash"}).
+		ExpectOption("repo", "r").
+		Validate()
+
+	completionErr, ok := err.(CompletionRequested)
+	if !ok {
+		t.Fatalf("Should have returned a CompletionRequested error, got %T.", err)
+	}
+
+	if !contains(completionErr.Script, "complete -F") {
+		t.Errorf("Should have included a generated bash script, got:\n%s", completionErr.Script)
+	}
+}
+
+func TestGenerateCompletionNotRequestedAfterSentinel(t *T) {
+	_, err := Load([]string{"--", "--generate-completion=bash"}).
+		ExpectParam().
+		Validate()
+
+	if err != nil {
+		t.Errorf("--generate-completion after the sentinel should be a plain positional, got: %v", err)
+	}
+}
+
+func assertStringsEqual(t *T, expected, actual []string) {
+	if len(expected) != len(actual) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+		return
+	}
+
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Errorf("Expected %v, got %v", expected, actual)
+			return
+		}
+	}
+}
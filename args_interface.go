@@ -0,0 +1,175 @@
+package args
+
+import "io"
+
+// Args is the fluent, immutable interface for describing and consuming a
+// set of command-line arguments.
+//
+// Every Allow/Expect method returns a new Args value rather than
+// mutating the receiver, so a chain can be branched or replayed without
+// surprises. The Chop methods mark the remaining arguments consumed in
+// place, so the Args they are called on sees that change as well.
+type Args interface {
+	// AllowFlag consumes a matching flag if present, without requiring it.
+	AllowFlag(name string, alts ...string) Args
+
+	// AllowOption consumes a matching option and its value if present,
+	// without requiring it.
+	AllowOption(name string, alts ...string) Args
+
+	// AllowParam consumes the next positional parameter, if there is one.
+	AllowParam() Args
+
+	// AllowParamNamed consumes the next positional parameter, if there is
+	// one, and assigns it the given name.
+	AllowParamNamed(name string) Args
+
+	// ExpectFlag consumes a matching flag. Validation will fail if it is
+	// not found.
+	ExpectFlag(name string, alts ...string) Args
+
+	// ExpectOption consumes a matching option and its value. Validation
+	// will fail if it is not found.
+	ExpectOption(name string, alts ...string) Args
+
+	// ExpectParam consumes the next positional parameter. Validation will
+	// fail if there are no more arguments to consume.
+	ExpectParam() Args
+
+	// ExpectParamNamed consumes the next positional parameter and assigns
+	// it the given name. Validation will fail if there are no more
+	// arguments to consume.
+	ExpectParamNamed(name string) Args
+
+	// HasFlag reports whether the named flag was Allowed or Expected.
+	HasFlag(name string) bool
+
+	// HasOption reports whether the named option was found.
+	HasOption(name string) bool
+
+	// HasParamAt reports whether there is a parameter at the given index.
+	HasParamAt(i int) bool
+
+	// HasParamNamed reports whether a parameter with the given name was
+	// consumed.
+	HasParamNamed(name string) bool
+
+	// Flag returns whether the named flag was set. Panics if the flag was
+	// never Allowed or Expected.
+	Flag(name string) bool
+
+	// Option returns the value of the named option. Panics if the option
+	// was never found.
+	Option(name string) string
+
+	// ParamAt returns the value of the parameter at the given index.
+	// Panics if there is no parameter at that index.
+	ParamAt(index int) string
+
+	// ParamNamed returns the value of the named parameter. Panics if no
+	// parameter was consumed with that name.
+	ParamNamed(name string) string
+
+	// Chop discards any remaining, unconsumed arguments.
+	Chop() Args
+
+	// ChopSlice consumes and returns any remaining arguments as a slice.
+	ChopSlice() (Args, []string)
+
+	// ChopString consumes and returns any remaining arguments, joined
+	// with spaces.
+	ChopString() (Args, string)
+
+	// ChopAndValidate discards any remaining arguments and calls Validate.
+	ChopAndValidate() (Args, error)
+
+	// Validate checks that every Expected argument was found and that no
+	// arguments were left unconsumed.
+	Validate() (Args, error)
+
+	// String returns a debug representation of the current state.
+	String() string
+
+	// Command registers a subcommand: if Dispatch finds name (or one of
+	// aliases) as the next unconsumed positional, handler is invoked
+	// with a fresh Args scoped to the remaining arguments.
+	Command(name string, handler func(Args) error, aliases ...string) Args
+
+	// DefaultCommand registers a handler to invoke from Dispatch when no
+	// positional is present, or it doesn't match any registered Command.
+	// Without one, Dispatch returns an error in either case.
+	DefaultCommand(handler func(Args) error) Args
+
+	// Dispatch consumes the next unconsumed positional as a verb, matches
+	// it (by exact name, alias, or unambiguous prefix) against the
+	// registered commands, and calls its handler.
+	Dispatch() error
+
+	// Commands returns the subcommands registered so far, for help
+	// generation.
+	Commands() []Command
+
+	// Describe attaches a description to the most recently registered
+	// flag, option or named parameter with the given name, for use by
+	// Usage.
+	Describe(name, text string) Args
+
+	// Usage writes aligned usage text, covering every flag, option,
+	// named parameter and subcommand registered so far.
+	Usage(w io.Writer)
+
+	// FromEnv falls back to the named environment variable for the
+	// option most recently registered with AllowOption/ExpectOption, if
+	// it wasn't supplied on the command line.
+	FromEnv(name string) Args
+
+	// FromConfigKey falls back to the named key, looked up in the
+	// Sources registered with LoadWithSources, for the option most
+	// recently registered with AllowOption/ExpectOption, if it wasn't
+	// supplied on the command line or by a preceding FromEnv.
+	FromConfigKey(key string) Args
+
+	// CompleteHint attaches a completion hint to the option most
+	// recently registered with AllowOption/ExpectOption, used by
+	// CompletionScript and Complete to suggest values for it.
+	CompleteHint(hint CompletionHint, choices ...string) Args
+
+	// CompletionScript generates a shell completion script (shell is
+	// "bash", "zsh" or "fish") covering every flag, option and
+	// subcommand registered so far. Positional parameters are not
+	// completed.
+	CompletionScript(shell string, progName string) (string, error)
+
+	// Complete returns candidate completions for words (the
+	// command-line tokens, excluding the program name) at cword, the
+	// index of the word under the cursor.
+	Complete(words []string, cword int) []string
+}
+
+// Load wraps a slice of command-line arguments (typically os.Args[1:]) in
+// an Args chain, ready for Allow/Expect calls.
+func Load(args []string) Args {
+	out := argv{
+		args:            make([]string, len(args)),
+		consumed:        make([]bool, len(args)),
+		flags:           make(map[string]bool),
+		options:         make(map[string]string),
+		namedParameters: make(map[string]int),
+		commands:        nil,
+		defaultCommand:  nil,
+		afterSentinel:   -1,
+		origins:         make(map[string]string),
+	}
+
+	copy(out.args, args)
+
+	for i, arg := range out.args {
+		if arg == "--" {
+			out.afterSentinel = i
+			out.consumed[i] = true
+			break
+		}
+	}
+
+	return out
+}
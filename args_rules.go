@@ -27,6 +27,39 @@ type argv struct {
 	// Map of parameter names to their index in
 	// the list of parameters.
 	namedParameters map[string]int
+
+	// Subcommands registered via Command, matched by Dispatch.
+	commands []command
+
+	// Handler to fall back on when Dispatch can't match a verb.
+	defaultCommand func(Args) error
+
+	// Flags, options and parameters, in registration order, for Usage.
+	usage []usageEntry
+
+	// Index of the "--" sentinel in args, or -1 if there isn't one.
+	// Flags and options are never matched at or beyond this index; every
+	// argument from here on is strictly positional.
+	afterSentinel int
+
+	// Name of the option most recently registered with AllowOption or
+	// ExpectOption, so a following FromEnv/FromConfigKey knows which
+	// option it applies to.
+	lastOption string
+
+	// Where each option's value came from ("cli", "env", "config", or
+	// "default" when Parse filled it from a default tag), keyed by name.
+	// Absent means "cli".
+	origins map[string]string
+
+	// Config sources consulted by FromConfigKey, in order.
+	sources []Source
+
+	// Set by Complete while probing a Dispatch tree for the scope the
+	// cursor falls in; nil during normal use. Shared (not deep-copied)
+	// across clones, since every level of the probed tree needs to
+	// write through to the same result.
+	probe *completionProbe
 }
 
 // Makes a copy of the structure.
@@ -38,12 +71,26 @@ func (orig argv) clone() (out argv) {
 	out.options			= make(map[string]string)
 	out.parameters		= make([]string, len(orig.parameters))
 	out.namedParameters	= make(map[string]int)
+	out.commands		= make([]command, len(orig.commands))
+	out.defaultCommand	= orig.defaultCommand
+	out.usage		= make([]usageEntry, len(orig.usage))
+	out.afterSentinel	= orig.afterSentinel
+	out.lastOption		= orig.lastOption
+	out.origins		= make(map[string]string)
+	out.sources		= orig.sources
+	out.probe		= orig.probe
 
 	copy(out.args, orig.args)
 	copy(out.consumed, orig.consumed)
 	copy(out.errors, orig.errors)
 	copy(out.parameters, orig.parameters)
-	
+	copy(out.commands, orig.commands)
+	copy(out.usage, orig.usage)
+
+	for key, val := range(orig.origins) {
+		out.origins[key] = val
+	}
+
 	for key, val := range(orig.flags) {
 		out.flags[key] = val
 	}
@@ -103,6 +150,14 @@ func (args argv) String() string {
 		fmt.Fprintf(&out, "%s => %d\n", name, i)
 	}
 
+	for name, val := range(args.options) {
+		origin := args.origins[name]
+		if origin == "" {
+			origin = "cli"
+		}
+		fmt.Fprintf(&out, "--%s => %s (%s)\n", name, val, origin)
+	}
+
 	return out.String()
 }
 
@@ -111,6 +166,7 @@ func (args argv) String() string {
 // name: The name of the flag to look for.
 func (chain argv) AllowFlag(name string, alts ...string) Args {
 	chain, _ = chain.getFlag(name, alts)
+	chain.usage = append(chain.usage, usageEntry{kind: usageFlag, name: name, alts: alts})
 	return chain
 }
 
@@ -126,6 +182,8 @@ func (chain argv) AllowFlag(name string, alts ...string) Args {
 // any of the alternate names.
 func (chain argv) AllowOption(name string, alts ...string) Args {
 	chain, _, _ = chain.getOption(name, alts)
+	chain.usage = append(chain.usage, usageEntry{kind: usageOption, name: name, alts: alts})
+	chain.lastOption = name
 
 	return chain
 }
@@ -135,6 +193,7 @@ func (chain argv) AllowOption(name string, alts ...string) Args {
 // If there are no more arguments to consume, nothing will be consumed.
 func (chain argv) AllowParam() Args {
 	chain, _, _ = chain.getParam()
+	chain.usage = append(chain.usage, usageEntry{kind: usageParam})
 
 	return chain
 }
@@ -152,6 +211,8 @@ func (chain argv) AllowParamNamed(name string) Args {
 		chain.namedParameters[name] = index
 	}
 
+	chain.usage = append(chain.usage, usageEntry{kind: usageParam, name: name})
+
 	return chain
 }
 
@@ -165,6 +226,8 @@ func (chain argv) ExpectFlag(name string, alts ...string) Args {
 		chain.errors = append(chain.errors, fmt.Errorf("Flag '%v' was expected and not found.", name))
 	}
 
+	chain.usage = append(chain.usage, usageEntry{kind: usageFlag, name: name, alts: alts, required: true})
+
 	return chain
 }
 
@@ -179,11 +242,13 @@ func (chain argv) ExpectFlag(name string, alts ...string) Args {
 // The option can only be accessed by its name or position, not by
 // any of the alternate names.
 func (chain argv) ExpectOption(name string, alts ...string) Args {
-	chain, _, found := chain.getOption(name, alts)
+	chain, _, _ = chain.getOption(name, alts)
 
-	if !found {
-		chain.errors = append(chain.errors, fmt.Errorf("Option '%v' was expected and not found.", name))
-	}
+	// Whether this is actually missing isn't decided until Validate, so
+	// that a FromEnv/FromConfigKey chained afterwards still has a chance
+	// to supply the value.
+	chain.usage = append(chain.usage, usageEntry{kind: usageOption, name: name, alts: alts, required: true})
+	chain.lastOption = name
 
 	return chain
 }
@@ -198,6 +263,8 @@ func (chain argv) ExpectParam() Args {
 		chain.errors = append(chain.errors, fmt.Errorf("No more arguments to consume."))
 	}
 
+	chain.usage = append(chain.usage, usageEntry{kind: usageParam, required: true})
+
 	return chain
 }
 
@@ -215,6 +282,8 @@ func (chain argv) ExpectParamNamed(name string) Args {
 		chain.errors = append(chain.errors, fmt.Errorf("No more arguments to consume."))
 	}
 
+	chain.usage = append(chain.usage, usageEntry{kind: usageParam, name: name, required: true})
+
 	return chain
 }
 
@@ -234,21 +303,42 @@ func (chain argv) getFlag(name string, alts []string) (out argv, present bool) {
 				continue
 			}
 
+			if out.afterSentinel >= 0 && i > out.afterSentinel {
+				continue
+			}
+
 			if len(n) == 1 {
-				if strings.HasPrefix(arg, "-") && arg[1:] == n {
+				if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+					continue
+				}
+
+				rest := arg[1:]
+
+				if rest == n {
 					present = true
+					out.consumed[i] = true
+				} else if strings.HasPrefix(rest, n) && len(rest) > len(n) && rest[len(n)] != '=' {
+					// Part of a bundle of short flags, e.g. "-abc". Strip
+					// off this letter and leave the remainder (still
+					// unconsumed) for a later Allow/Expect call to match.
+					present = true
+					out.args[i] = "-" + rest[len(n):]
 				}
 			} else {
 				if strings.HasPrefix(arg, "--") && arg[2:] == n {
 					present = true
+					out.consumed[i] = true
 				}
 			}
 
-			if (present) {
-				out.consumed[i] = true
+			if present {
 				break
 			}
 		}
+
+		if present {
+			break
+		}
 	}
 
 	out.flags[name] = present
@@ -268,30 +358,55 @@ func (chain argv) getOption(name string, alts []string) (out argv, val string, f
 	names = append(names, alts...)
 
 	for _, n := range names {
-		for i, arg := range out.args {
-			if out.consumed[i] {
+		for i, arg := range chain.args {
+			if chain.consumed[i] {
 				continue
 			}
 
+			if chain.afterSentinel >= 0 && i > chain.afterSentinel {
+				continue
+			}
+
+			prefix := "--" + n
 			if len(n) == 1 {
-				if strings.HasPrefix(arg, "-") && arg[1:] == n && len(out.args) > i+1 {
-					found = true
-				}
-			} else {
-				if strings.HasPrefix(arg, "--") && arg[2:] == n && len(out.args) > i+1 {
-					found = true
-				}
+				prefix = "-" + n
 			}
 
-			if found {
+			if !strings.HasPrefix(arg, prefix) {
+				continue
+			}
+
+			switch {
+			case arg == prefix && len(chain.args) > i+1:
+				// "--name value" / "-n value"
 				out = chain.clone()
-				val = out.args[i+1]
+				val = chain.args[i+1]
 				out.consumed[i] = true
 				out.consumed[i+1] = true
+				found = true
+
+			case strings.HasPrefix(arg, prefix+"="):
+				// "--name=value" / "-n=value"
+				out = chain.clone()
+				val = arg[len(prefix)+1:]
+				out.consumed[i] = true
+				found = true
+
+			case len(n) == 1 && len(arg) > len(prefix) && arg[len(prefix)] != '=':
+				// "-nvalue": a short option with its value attached,
+				// possibly after other short flags have been stripped
+				// off a bundle such as "-abcvalue".
+				out = chain.clone()
+				val = arg[len(prefix):]
+				out.consumed[i] = true
+				found = true
+			}
+
+			if found {
 				break
 			}
 		}
-		
+
 		if found {
 			break
 		}
@@ -0,0 +1,161 @@
+package args
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source looks up a configuration value by key. Implementations are
+// consulted by FromConfigKey, in the order they were registered with
+// LoadWithSources.
+type Source interface {
+	Lookup(name string) (string, bool)
+}
+
+// LoadWithSources wraps args (typically os.Args[1:]) in an Args chain,
+// as Load does, and additionally registers sources to be consulted by
+// FromConfigKey.
+func LoadWithSources(args []string, sources ...Source) Args {
+	out := Load(args).(argv)
+	out.sources = sources
+	return out
+}
+
+// Falls back to the named environment variable for the option most
+// recently registered with AllowOption/ExpectOption, if the option
+// wasn't supplied on the command line.
+// name: The environment variable to consult.
+func (chain argv) FromEnv(name string) Args {
+	return chain.fallback("env", func() (string, bool) {
+		return os.LookupEnv(name)
+	})
+}
+
+// Falls back to the named key, looked up in the Sources registered with
+// LoadWithSources, for the option most recently registered with
+// AllowOption/ExpectOption, if the option wasn't supplied on the command
+// line or by a preceding FromEnv.
+// key: The key to look up in each registered Source, in order.
+func (chain argv) FromConfigKey(key string) Args {
+	return chain.fallback("config", func() (string, bool) {
+		for _, source := range(chain.sources) {
+			if value, ok := source.Lookup(key); ok {
+				return value, true
+			}
+		}
+
+		return "", false
+	})
+}
+
+// Shared by FromEnv and FromConfigKey: fills in the most recently
+// registered option's value, and records where it came from, unless a
+// value (from the command line, or an earlier fallback) is already
+// present.
+func (chain argv) fallback(origin string, lookup func() (string, bool)) Args {
+	out := chain.clone()
+
+	name := out.lastOption
+	if name == "" {
+		return out
+	}
+
+	if _, present := out.options[name]; present {
+		return out
+	}
+
+	if value, ok := lookup(); ok {
+		out.options[name] = value
+		out.origins[name] = origin
+	}
+
+	return out
+}
+
+// IniSource reads "key = value" pairs from an INI-style file. Section
+// headers and comment lines (starting with ';' or '#') are ignored, and
+// every key is looked up in a single flat namespace.
+type IniSource struct {
+	values map[string]string
+}
+
+// Reads an INI-style file for use as a Source.
+// path: The path to the file to read.
+func LoadIniFile(path string) (IniSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return IniSource{}, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return IniSource{}, err
+	}
+
+	return IniSource{values}, nil
+}
+
+// Lookup implements Source.
+func (s IniSource) Lookup(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
+
+// JsonSource reads key/value pairs from a flat JSON object. Non-string
+// values are converted with fmt.Sprint.
+type JsonSource struct {
+	values map[string]string
+}
+
+// Reads a JSON file (a single flat object) for use as a Source.
+// path: The path to the file to read.
+func LoadJsonFile(path string) (JsonSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return JsonSource{}, err
+	}
+	defer f.Close()
+
+	var raw map[string]interface{}
+	if err := json.NewDecoder(f).Decode(&raw); err != nil {
+		return JsonSource{}, err
+	}
+
+	values := make(map[string]string)
+	for key, value := range(raw) {
+		values[key] = fmt.Sprint(value)
+	}
+
+	return JsonSource{values}, nil
+}
+
+// Lookup implements Source.
+func (s JsonSource) Lookup(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
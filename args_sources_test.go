@@ -0,0 +1,126 @@
+package args
+
+import (
+	"os"
+	. "testing"
+)
+
+type mapSource map[string]string
+
+func (s mapSource) Lookup(name string) (string, bool) {
+	value, ok := s[name]
+	return value, ok
+}
+
+func TestFromEnvFallback(t *T) {
+	os.Setenv("ARGS_TEST_REPO", "/from/env")
+	defer os.Unsetenv("ARGS_TEST_REPO")
+
+	result, err := Load([]string{}).
+		ExpectOption("repo", "r").FromEnv("ARGS_TEST_REPO").
+		ChopAndValidate()
+
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	assertStringEquals(t, "/from/env", result.Option("repo"))
+}
+
+func TestCliTakesPrecedenceOverEnv(t *T) {
+	os.Setenv("ARGS_TEST_REPO", "/from/env")
+	defer os.Unsetenv("ARGS_TEST_REPO")
+
+	result, err := Load([]string{"--repo", "/from/cli"}).
+		ExpectOption("repo", "r").FromEnv("ARGS_TEST_REPO").
+		ChopAndValidate()
+
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	assertStringEquals(t, "/from/cli", result.Option("repo"))
+}
+
+func TestFromConfigKeyFallback(t *T) {
+	source := mapSource{"repo": "/from/config"}
+
+	result, err := LoadWithSources([]string{}, source).
+		ExpectOption("repo", "r").FromConfigKey("repo").
+		ChopAndValidate()
+
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	assertStringEquals(t, "/from/config", result.Option("repo"))
+}
+
+func TestEnvTakesPrecedenceOverConfig(t *T) {
+	os.Setenv("ARGS_TEST_REPO", "/from/env")
+	defer os.Unsetenv("ARGS_TEST_REPO")
+
+	source := mapSource{"repo": "/from/config"}
+
+	result, err := LoadWithSources([]string{}, source).
+		ExpectOption("repo", "r").FromEnv("ARGS_TEST_REPO").FromConfigKey("repo").
+		ChopAndValidate()
+
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	assertStringEquals(t, "/from/env", result.Option("repo"))
+}
+
+func TestMissingOptionStillFailsValidation(t *T) {
+	_, err := Load([]string{}).
+		ExpectOption("repo", "r").
+		ChopAndValidate()
+
+	if err == nil {
+		t.Errorf("Should have returned an error for the missing option.")
+	}
+}
+
+func TestIniSource(t *T) {
+	f, err := os.CreateTemp("", "args-test-*.ini")
+	if err != nil {
+		t.Fatalf("Could not create a temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString("; a comment\n[section]\nrepo = /from/ini\n")
+	f.Close()
+
+	source, err := LoadIniFile(f.Name())
+	if err != nil {
+		t.Fatalf("Should not have returned an error: %v", err)
+	}
+
+	value, ok := source.Lookup("repo")
+	if !ok || value != "/from/ini" {
+		t.Errorf("Should have found 'repo' in the INI file, got %q, %v.", value, ok)
+	}
+}
+
+func TestJsonSource(t *T) {
+	f, err := os.CreateTemp("", "args-test-*.json")
+	if err != nil {
+		t.Fatalf("Could not create a temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString(`{"repo": "/from/json"}`)
+	f.Close()
+
+	source, err := LoadJsonFile(f.Name())
+	if err != nil {
+		t.Fatalf("Should not have returned an error: %v", err)
+	}
+
+	value, ok := source.Lookup("repo")
+	if !ok || value != "/from/json" {
+		t.Errorf("Should have found 'repo' in the JSON file, got %q, %v.", value, ok)
+	}
+}
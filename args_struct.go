@@ -0,0 +1,358 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Implemented by a field type to take over its own conversion from the
+// string form of a flag/option value, for types Parse does not know
+// about natively.
+// value: The raw string found on the command line, in the environment,
+// or from a "default" tag.
+type UnmarshalFlag interface {
+	UnmarshalFlag(value string) error
+}
+
+// Populates the fields of opts (a pointer to a struct) from argv, driven
+// by struct tags:
+//
+//	short        a single-character alternate name, e.g. `short:"r"`
+//	long         the primary name; defaults to the field name with its
+//	             first letter lowercased
+//	description  text to show in generated usage output
+//	required     "true" to fail validation if no value is supplied
+//	default      a value to use if none is supplied on the command line
+//	env          an environment variable to fall back to
+//	choice       (repeatable) restricts the value to a fixed set
+//
+// Supported field types are bool, int, string, []string (may appear more
+// than once on the command line), time.Duration, and any type
+// implementing UnmarshalFlag.
+//
+// The default and env tags are only consulted for single-value fields
+// (int, string, time.Duration and UnmarshalFlag types); bool and
+// []string fields take their value from the command line alone.
+//
+// opts: A pointer to the struct to populate.
+func Parse(argv []string, opts interface{}) (Args, error) {
+	v := reflect.ValueOf(opts)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Parse requires a pointer to a struct, got %T.", opts)
+	}
+
+	v = v.Elem()
+	t := v.Type()
+
+	chain := Load(argv)
+
+	for i := 0; i < t.NumField(); i += 1 {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if !value.CanSet() {
+			continue
+		}
+
+		tag := parseFieldTag(field)
+
+		var err error
+		chain, err = tag.apply(chain, value)
+		if err != nil {
+			return chain, err
+		}
+
+		if tag.description != "" {
+			chain = chain.Describe(tag.long, tag.description)
+		}
+	}
+
+	return chain.Validate()
+}
+
+// Describes how a single struct field maps onto the Allow/Expect chain.
+type fieldTag struct {
+	short       string
+	long        string
+	description string
+	required    bool
+	defaultVal  string
+	env         string
+	choices     []string
+}
+
+func parseFieldTag(field reflect.StructField) fieldTag {
+	long, ok := field.Tag.Lookup("long")
+	if !ok || long == "" {
+		long = lowerFirst(field.Name)
+	}
+
+	return fieldTag{
+		short:       field.Tag.Get("short"),
+		long:        long,
+		description: field.Tag.Get("description"),
+		required:    field.Tag.Get("required") == "true",
+		defaultVal:  field.Tag.Get("default"),
+		env:         field.Tag.Get("env"),
+		choices:     tagValues(field.Tag, "choice"),
+	}
+}
+
+func lowerFirst(name string) string {
+	r := []rune(name)
+	if len(r) == 0 {
+		return name
+	}
+
+	if r[0] >= 'A' && r[0] <= 'Z' {
+		r[0] += 'a' - 'A'
+	}
+
+	return string(r)
+}
+
+func (tag fieldTag) alts() []string {
+	if tag.short == "" {
+		return nil
+	}
+
+	return []string{tag.short}
+}
+
+func (tag fieldTag) apply(chain Args, value reflect.Value) (Args, error) {
+	if unmarshaler, ok := addr(value).Interface().(UnmarshalFlag); ok {
+		return tag.applyString(chain, unmarshaler.UnmarshalFlag)
+	}
+
+	switch {
+	case value.Kind() == reflect.Bool:
+		return tag.applyFlag(chain, value)
+
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		return tag.applyStringSlice(chain, value)
+
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		return tag.applyString(chain, func(raw string) error {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(d))
+			return nil
+		})
+
+	case value.Kind() == reflect.Int:
+		return tag.applyString(chain, func(raw string) error {
+			i, err := strconv.ParseInt(raw, 0, 64)
+			if err != nil {
+				return err
+			}
+			value.SetInt(i)
+			return nil
+		})
+
+	case value.Kind() == reflect.String:
+		return tag.applyString(chain, func(raw string) error {
+			value.SetString(raw)
+			return nil
+		})
+
+	default:
+		return chain, fmt.Errorf("Parse: field type %s is not supported.", value.Type())
+	}
+}
+
+func addr(value reflect.Value) reflect.Value {
+	if value.CanAddr() {
+		return value.Addr()
+	}
+
+	return reflect.New(value.Type())
+}
+
+// Consumes a flag (boolean) field.
+func (tag fieldTag) applyFlag(chain Args, value reflect.Value) (Args, error) {
+	if tag.required {
+		chain = chain.ExpectFlag(tag.long, tag.alts()...)
+	} else {
+		chain = chain.AllowFlag(tag.long, tag.alts()...)
+	}
+
+	value.SetBool(chain.Flag(tag.long))
+
+	return chain, nil
+}
+
+// Consumes a single-value option (string, int, Duration, UnmarshalFlag),
+// falling back to the env tag and then the default tag if the option
+// wasn't present on the command line.
+func (tag fieldTag) applyString(chain Args, set func(string) error) (Args, error) {
+	chain = chain.AllowOption(tag.long, tag.alts()...)
+
+	raw, origin := "", ""
+	if chain.HasOption(tag.long) {
+		raw, origin = chain.Option(tag.long), "cli"
+	} else if tag.env != "" {
+		if v, ok := os.LookupEnv(tag.env); ok {
+			raw, origin = v, "env"
+		}
+	}
+
+	if origin == "" && tag.defaultVal != "" {
+		raw, origin = tag.defaultVal, "default"
+	}
+
+	if origin == "" {
+		if tag.required {
+			chain = requireOption(chain, tag.long)
+		}
+		return chain, nil
+	}
+
+	if err := tag.checkChoice(raw); err != nil {
+		return chain, err
+	}
+
+	if err := set(raw); err != nil {
+		return chain, fmt.Errorf("Option '%s': %v", tag.long, err)
+	}
+
+	if origin != "cli" {
+		chain = recordOption(chain, tag.long, raw, origin)
+	}
+
+	return chain, nil
+}
+
+// Records a value resolved from outside the command line (the env or
+// default tag) directly into the chain's options, the same way
+// AllowOption/getOption would for a CLI-supplied value, so the returned
+// Args doesn't lie about HasOption/Option for fields that Parse filled
+// in itself.
+func recordOption(chain Args, name, value, origin string) Args {
+	a := chain.(argv)
+	a.options[name] = value
+	a.origins[name] = origin
+	return a
+}
+
+// Consumes every occurrence of a repeatable option, in order. This digs
+// into the argv implementation directly (rather than the Args interface)
+// because Option only ever exposes the most recently matched value.
+func (tag fieldTag) applyStringSlice(chain Args, value reflect.Value) (Args, error) {
+	// Registers a usage entry (and consumes the first occurrence, if
+	// any) the same way every other field type does, so the option
+	// shows up in Usage/CompletionScript/Complete even though the rest
+	// of its occurrences are collected below via getOption directly.
+	chain = chain.AllowOption(tag.long, tag.alts()...)
+
+	a := chain.(argv)
+	var values []string
+
+	if a.HasOption(tag.long) {
+		first := a.Option(tag.long)
+
+		if err := tag.checkChoice(first); err != nil {
+			return a, err
+		}
+
+		values = append(values, first)
+	}
+
+	for {
+		next, val, found := a.getOption(tag.long, tag.alts())
+		if !found {
+			break
+		}
+
+		if err := tag.checkChoice(val); err != nil {
+			return next, err
+		}
+
+		values = append(values, val)
+		a = next
+	}
+
+	if len(values) == 0 && tag.required {
+		a = requireOption(a, tag.long).(argv)
+	}
+
+	value.Set(reflect.ValueOf(values))
+
+	return a, nil
+}
+
+func (tag fieldTag) checkChoice(raw string) error {
+	if len(tag.choices) == 0 {
+		return nil
+	}
+
+	for _, choice := range tag.choices {
+		if raw == choice {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Option '%s' must be one of %v, got '%s'.", tag.long, tag.choices, raw)
+}
+
+func requireOption(chain Args, name string) Args {
+	a := chain.(argv)
+	a.errors = append(a.errors, fmt.Errorf("Option '%v' was expected and not found.", name))
+	return a
+}
+
+// Collects every value assigned to a repeatable struct tag, e.g.
+// `choice:"a" choice:"b"`, which reflect.StructTag can't do on its own
+// since it only ever returns the first match for a given key.
+func tagValues(tag reflect.StructTag, key string) []string {
+	raw := string(tag)
+	var values []string
+
+	for raw != "" {
+		i := 0
+		for i < len(raw) && raw[i] == ' ' {
+			i += 1
+		}
+		raw = raw[i:]
+		if raw == "" {
+			break
+		}
+
+		i = 0
+		for i < len(raw) && raw[i] > ' ' && raw[i] != ':' && raw[i] != '"' {
+			i += 1
+		}
+		if i == 0 || i+1 >= len(raw) || raw[i] != ':' || raw[i+1] != '"' {
+			break
+		}
+
+		name := raw[:i]
+		raw = raw[i+1:]
+
+		i = 1
+		for i < len(raw) && raw[i] != '"' {
+			if raw[i] == '\\' {
+				i += 1
+			}
+			i += 1
+		}
+		if i >= len(raw) {
+			break
+		}
+
+		qvalue := raw[:i+1]
+		raw = raw[i+1:]
+
+		if name == key {
+			if value, err := strconv.Unquote(qvalue); err == nil {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,117 @@
+package args
+
+import (
+	"bytes"
+	"os"
+	. "testing"
+	"time"
+)
+
+type testOptions struct {
+	Repo     string        `short:"r" long:"repo" required:"true"`
+	Verbose  bool          `short:"v" long:"verbose"`
+	Retries  int           `long:"retries" default:"3"`
+	Timeout  time.Duration `long:"timeout" default:"5s"`
+	Excludes []string      `short:"e" long:"exclude"`
+	Mode     string        `long:"mode" choice:"fast" choice:"slow" default:"fast"`
+}
+
+func TestParseStruct(t *T) {
+	var opts testOptions
+
+	result, err := Parse([]string{
+		"--repo", "/backups",
+		"-v",
+		"--exclude", "*.tmp",
+		"--exclude", "*.log",
+	}, &opts)
+
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	assertStringEquals(t, "/backups", opts.Repo)
+	assertBoolEquals(t, true, opts.Verbose)
+
+	if opts.Retries != 3 {
+		t.Errorf("Should have fallen back to the default value of 3, got %v.", opts.Retries)
+	}
+
+	if opts.Timeout != 5*time.Second {
+		t.Errorf("Should have fallen back to the default value of 5s, got %v.", opts.Timeout)
+	}
+
+	if !argsEqual(opts.Excludes, []string{"*.tmp", "*.log"}) {
+		t.Errorf("Should have collected every occurrence of --exclude, got %v.", opts.Excludes)
+	}
+
+	assertStringEquals(t, "fast", opts.Mode)
+
+	// A field filled in from the default tag should still be visible on
+	// the returned Args, not just the struct.
+	if !result.HasOption("retries") {
+		t.Errorf("Should have recorded 'retries' as an option on the returned Args.")
+	}
+
+	assertStringEquals(t, "3", result.Option("retries"))
+}
+
+func TestParseStructSliceFieldAppearsInUsage(t *T) {
+	var opts testOptions
+
+	result, err := Parse([]string{"--repo", "/backups", "--exclude", "*.tmp"}, &opts)
+	if err != nil {
+		t.Fatalf("Should not have returned an error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	result.Usage(&buf)
+
+	if !contains(buf.String(), "--exclude") {
+		t.Errorf("Should have listed --exclude in usage output, got:\n%s", buf.String())
+	}
+}
+
+func TestParseStructRecordsEnvOrigin(t *T) {
+	os.Setenv("ARGS_TEST_MODE", "slow")
+	defer os.Unsetenv("ARGS_TEST_MODE")
+
+	var opts struct {
+		Mode string `long:"mode" env:"ARGS_TEST_MODE"`
+	}
+
+	result, err := Parse([]string{}, &opts)
+	if err != nil {
+		t.Errorf("Should not have returned an error: %v", err)
+	}
+
+	assertStringEquals(t, "slow", opts.Mode)
+
+	if !result.HasOption("mode") {
+		t.Errorf("Should have recorded 'mode' as an option on the returned Args.")
+	}
+
+	assertStringEquals(t, "slow", result.Option("mode"))
+
+	if !contains(result.String(), "--mode => slow (env)") {
+		t.Errorf("Should have recorded 'env' as the origin of 'mode', got:\n%s", result.String())
+	}
+}
+
+func TestParseStructMissingRequired(t *T) {
+	var opts testOptions
+
+	_, err := Parse([]string{"-v"}, &opts)
+	if err == nil {
+		t.Errorf("Should have returned an error for the missing --repo option.")
+	}
+}
+
+func TestParseStructRejectsBadChoice(t *T) {
+	var opts testOptions
+
+	_, err := Parse([]string{"--repo", "/backups", "--mode", "medium"}, &opts)
+	if err == nil {
+		t.Errorf("Should have returned an error for an unlisted choice.")
+	}
+}
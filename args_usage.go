@@ -0,0 +1,179 @@
+package args
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type usageKind int
+
+const (
+	usageFlag usageKind = iota
+	usageOption
+	usageParam
+)
+
+// A single flag, option or parameter registered via Allow*/Expect*,
+// tracked in registration order so Usage can print them that way.
+type usageEntry struct {
+	kind        usageKind
+	name        string
+	alts        []string
+	required    bool
+	description string
+
+	// Set by CompleteHint, for use by CompletionScript and Complete.
+	hint        CompletionHint
+	hintChoices []string
+}
+
+func (e usageEntry) label() string {
+	if e.kind == usageParam {
+		return e.name
+	}
+
+	names := make([]string, 0, len(e.alts)+1)
+	names = append(names, formatFlagName(e.name))
+
+	for _, alt := range e.alts {
+		names = append(names, formatFlagName(alt))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func formatFlagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+
+	return "--" + name
+}
+
+// Attaches a description to the most recently registered flag, option or
+// named parameter with the given name, for use by Usage.
+// name: The primary name the flag, option or parameter was registered
+// with.
+// text: The description to show next to it in usage output.
+func (chain argv) Describe(name, text string) Args {
+	out := chain.clone()
+
+	for i := len(out.usage) - 1; i >= 0; i -= 1 {
+		if out.usage[i].name == name {
+			out.usage[i].description = text
+			break
+		}
+	}
+
+	return out
+}
+
+// Writes aligned usage text to w: a synopsis line, a parameters section
+// for any named parameters, an options section for flags and options,
+// and a commands section for any registered subcommands.
+func (chain argv) Usage(w io.Writer) {
+	var params, opts []usageEntry
+
+	for _, e := range chain.usage {
+		switch e.kind {
+		case usageParam:
+			if e.name != "" {
+				params = append(params, e)
+			}
+		default:
+			opts = append(opts, e)
+		}
+	}
+
+	fmt.Fprint(w, "Usage:")
+	for _, e := range params {
+		if e.required {
+			fmt.Fprintf(w, " <%s>", e.name)
+		} else {
+			fmt.Fprintf(w, " [%s]", e.name)
+		}
+	}
+	if len(opts) > 0 {
+		fmt.Fprint(w, " [options]")
+	}
+	if len(chain.commands) > 0 {
+		fmt.Fprint(w, " <command>")
+	}
+	fmt.Fprintln(w)
+
+	if len(params) > 0 {
+		fmt.Fprintln(w, "\nParameters:")
+		writeAligned(w, params)
+	}
+
+	if len(opts) > 0 {
+		fmt.Fprintln(w, "\nOptions:")
+		writeAligned(w, opts)
+	}
+
+	if len(chain.commands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		for _, c := range chain.commands {
+			label := c.name
+			if len(c.aliases) > 0 {
+				label += ", " + strings.Join(c.aliases, ", ")
+			}
+			fmt.Fprintf(w, "  %s\n", label)
+		}
+	}
+}
+
+func writeAligned(w io.Writer, entries []usageEntry) {
+	width := 0
+	for _, e := range entries {
+		if len(e.label()) > width {
+			width = len(e.label())
+		}
+	}
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "  %-*s", width, e.label())
+		if e.description != "" {
+			fmt.Fprintf(w, "  %s", e.description)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func (chain argv) usageString() string {
+	var buf bytes.Buffer
+	chain.Usage(&buf)
+	return buf.String()
+}
+
+// Returned by Validate (and ChopAndValidate) when "--help" or "-h" was
+// present among the arguments, in place of running normal validation.
+type HelpRequested struct {
+	// The usage text that would otherwise have been printed.
+	Usage string
+}
+
+// Display string for HelpRequested; this is the usage text itself.
+func (h HelpRequested) Error() string {
+	return h.Usage
+}
+
+func (chain argv) helpRequested() bool {
+	for i, arg := range chain.args {
+		if chain.consumed[i] {
+			continue
+		}
+
+		if chain.afterSentinel >= 0 && i > chain.afterSentinel {
+			continue
+		}
+
+		if arg == "--help" || arg == "-h" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package args
+
+import (
+	"bytes"
+	. "testing"
+)
+
+func TestUsage(t *T) {
+	var buf bytes.Buffer
+
+	Load([]string{}).
+		ExpectParamNamed("file").Describe("file", "the file to process").
+		AllowFlag("verbose", "v").Describe("verbose", "enable verbose logging").
+		Usage(&buf)
+
+	out := buf.String()
+
+	if !contains(out, "Usage:") {
+		t.Errorf("Should have included a synopsis line, got:\n%s", out)
+	}
+	if !contains(out, "the file to process") {
+		t.Errorf("Should have included the parameter's description, got:\n%s", out)
+	}
+	if !contains(out, "--verbose, -v") {
+		t.Errorf("Should have listed the flag's primary name and alternates, got:\n%s", out)
+	}
+	if !contains(out, "enable verbose logging") {
+		t.Errorf("Should have included the flag's description, got:\n%s", out)
+	}
+}
+
+func TestValidateHelpRequested(t *T) {
+	_, err := Load([]string{"--help"}).
+		ExpectOption("repo", "r").
+		Validate()
+
+	if err == nil {
+		t.Fatalf("Should have returned an error.")
+	}
+
+	if _, ok := err.(HelpRequested); !ok {
+		t.Errorf("Should have returned a HelpRequested error, got %T.", err)
+	}
+}
+
+func TestValidateHelpNotRequestedWhenConsumedOrAfterSentinel(t *T) {
+	_, err := Load([]string{"-h", "myhost"}).
+		ExpectOption("host", "h").
+		Validate()
+
+	if err != nil {
+		t.Errorf("An already-consumed -h shouldn't trigger help, got: %v", err)
+	}
+
+	_, err = Load([]string{"--", "--help"}).
+		ExpectParam().
+		Validate()
+
+	if err != nil {
+		t.Errorf("--help after the sentinel should be a plain positional, got: %v", err)
+	}
+}
+
+func TestValidateErrorIncludesUsage(t *T) {
+	_, err := Load([]string{}).
+		ExpectOption("repo", "r").
+		Validate()
+
+	argsErr, ok := err.(ArgsError)
+	if !ok {
+		t.Fatalf("Should have returned an ArgsError, got %T.", err)
+	}
+
+	if !contains(argsErr.Usage, "--repo, -r") {
+		t.Errorf("Should have included usage text describing the missing option, got:\n%s", argsErr.Usage)
+	}
+}
+
+func contains(haystack, needle string) bool {
+	return bytes.Contains([]byte(haystack), []byte(needle))
+}
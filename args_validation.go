@@ -68,6 +68,39 @@ func (final argv) ChopAndValidate() (result Args, err error) {
 // 2. Any Expected arguments were not found.
 //    Allowed arguments will not cause validation errors when missing. 
 func (final argv) Validate() (result Args, err error) {
+	if final.probe != nil {
+		final.probe.scope = final
+	}
+
+	if shell, ok := final.completionShellRequested(); ok {
+		result = final
+
+		script, scriptErr := final.CompletionScript(shell, progName())
+		if scriptErr != nil {
+			err = scriptErr
+		} else {
+			err = CompletionRequested{script}
+		}
+
+		return
+	}
+
+	if final.helpRequested() {
+		result = final
+		err = HelpRequested{final.usageString()}
+		return
+	}
+
+	for _, e := range final.usage {
+		if !e.required || e.kind != usageOption {
+			continue
+		}
+
+		if _, present := final.options[e.name]; !present {
+			final.errors = append(final.errors, fmt.Errorf("Option '%v' was expected and not found.", e.name))
+		}
+	}
+
 	count := 0
 
 	for _, consumed := range final.consumed {
@@ -81,7 +114,7 @@ func (final argv) Validate() (result Args, err error) {
 	}
 
 	if len(final.errors) > 0 {
-		err = ArgsError{final.errors}
+		err = ArgsError{final.errors, final.usageString()}
 	}
 
 	result = final
@@ -94,11 +127,15 @@ func (final argv) Validate() (result Args, err error) {
 // that were encountered. 
 type ArgsError struct {
 	Errors []error
+
+	// Usage text for the Args chain that failed validation, so callers
+	// can optionally show it alongside the errors.
+	Usage string
 }
 
 // Display string for ArgsError.
 //
-// Displays the list of validation errors. 
+// Displays the list of validation errors.
 func (argsError ArgsError) Error() string {
 	return fmt.Sprintf("Validation failed: %v", argsError.Errors)
 }